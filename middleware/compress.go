@@ -0,0 +1,131 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compress gzip- or deflate-compresses the response body, chosen from the
+// request's Accept-Encoding header (gzip preferred), at the given level.
+// If types is non-empty, only responses whose Content-Type has one of
+// types as a prefix are compressed; otherwise every response is. level
+// must be a valid compress/flate level (gzip and flate share the same
+// range), or Compress panics; better to fail at setup than to panic with
+// a nil writer on the first request.
+func Compress(level int, types ...string) func(http.HandlerFunc) http.HandlerFunc {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic("middleware: invalid compress level " + strconv.Itoa(level) + ": " + err.Error())
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, level: level, types: types}
+			defer cw.Close()
+
+			next(cw, r)
+		}
+	}
+}
+
+// acceptedEncoding picks gzip or deflate out of an Accept-Encoding header,
+// preferring gzip, or "" if the client supports neither.
+func acceptedEncoding(header string) string {
+	switch {
+	case strings.Contains(header, "gzip"):
+		return "gzip"
+	case strings.Contains(header, "deflate"):
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter lazily wraps an http.ResponseWriter in a gzip or flate
+// writer on the first Write or WriteHeader, once the handler's
+// Content-Type is known.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	level    int
+	types    []string
+
+	decided  bool
+	compress bool
+	writer   io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	w.prepare()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.prepare()
+
+	if !w.compress {
+		return w.ResponseWriter.Write(b)
+	}
+
+	return w.writer.Write(b)
+}
+
+// prepare decides, on the first Write or WriteHeader, whether this
+// response should be compressed, and sets up the compressing writer.
+func (w *compressWriter) prepare() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	if !w.allowedType(w.Header().Get("Content-Type")) {
+		return
+	}
+
+	w.compress = true
+	w.Header().Set("Content-Encoding", w.encoding)
+	w.Header().Del("Content-Length")
+
+	if w.encoding == "gzip" {
+		w.writer, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+	} else {
+		w.writer, _ = flate.NewWriter(w.ResponseWriter, w.level)
+	}
+}
+
+func (w *compressWriter) allowedType(contentType string) bool {
+	if len(w.types) == 0 {
+		return true
+	}
+
+	for _, t := range w.types {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Close flushes and closes the underlying compressing writer, if one was
+// created for this response.
+func (w *compressWriter) Close() error {
+	if w.writer == nil {
+		return nil
+	}
+
+	return w.writer.Close()
+}
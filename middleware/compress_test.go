@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompressInvalidLevelPanicsAtSetup(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Compress(99) to panic")
+		}
+	}()
+
+	Compress(99)
+}
+
+func TestCompressValidLevelGzipsBody(t *testing.T) {
+	h := Compress(6)(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello world"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	h(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil || string(body) != "hello world" {
+		t.Fatalf("decompressed body = %q (err %v), want hello world", body, err)
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Logger logs one structured access line per request: method, path,
+// response status, bytes written and latency.
+func Logger(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ww := WrapResponseWriter(w)
+		start := time.Now()
+
+		next(ww, r)
+
+		log.Printf("%s %s %d %dB %s", r.Method, r.URL.Path, ww.Status(), ww.BytesWritten(), time.Since(start))
+	}
+}
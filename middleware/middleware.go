@@ -0,0 +1,64 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+// Package middleware provides a small set of composable middlewares for
+// use with sariaf.Router.Use, covering the common concerns of a
+// batteries-included HTTP stack: panic recovery, access logging, client
+// IP resolution, request IDs, timeouts and response compression.
+package middleware
+
+import "net/http"
+
+// ResponseWriter wraps http.ResponseWriter to capture the status code and
+// number of bytes written, so a middleware such as Logger can report on
+// the response after the handler has run. Compress does not use it; it
+// wraps the response in its own compressing writer.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// WrapResponseWriter returns a ResponseWriter wrapping w.
+func WrapResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// WriteHeader records status before delegating to the underlying
+// ResponseWriter. Only the first call has an effect, matching the
+// behavior of the standard library's ResponseWriter.
+func (w *ResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written, calling WriteHeader(200)
+// first if the handler hasn't set a status yet.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+
+	return n, err
+}
+
+// Status returns the status code written to the response, or 0 if the
+// handler hasn't written one yet.
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+// BytesWritten returns the number of bytes written to the response body.
+func (w *ResponseWriter) BytesWritten() int {
+	return w.bytes
+}
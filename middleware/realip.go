@@ -0,0 +1,30 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RealIP overwrites r.RemoteAddr with the client address found in the
+// X-Forwarded-For or X-Real-IP headers, for use behind a reverse proxy.
+// It trusts those headers unconditionally, so it should only be used
+// behind a proxy that's known to set (and not simply forward) them.
+func RealIP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			if i := strings.IndexByte(fwd, ','); i != -1 {
+				fwd = fwd[:i]
+			}
+
+			r.RemoteAddr = strings.TrimSpace(fwd)
+		} else if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			r.RemoteAddr = ip
+		}
+
+		next(w, r)
+	}
+}
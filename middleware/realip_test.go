@@ -0,0 +1,62 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPPrefersXForwardedFor(t *testing.T) {
+	var got string
+	h := RealIP(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.1, 10.0.0.1")
+	req.Header.Set("X-Real-IP", "198.51.100.1")
+	req.RemoteAddr = "127.0.0.1:1234"
+
+	h(httptest.NewRecorder(), req)
+
+	if got != "203.0.113.1" {
+		t.Fatalf("RemoteAddr = %q, want the first X-Forwarded-For entry", got)
+	}
+}
+
+func TestRealIPFallsBackToXRealIP(t *testing.T) {
+	var got string
+	h := RealIP(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Real-IP", "198.51.100.1")
+	req.RemoteAddr = "127.0.0.1:1234"
+
+	h(httptest.NewRecorder(), req)
+
+	if got != "198.51.100.1" {
+		t.Fatalf("RemoteAddr = %q, want X-Real-IP", got)
+	}
+}
+
+func TestRealIPLeavesRemoteAddrWhenNoHeadersSet(t *testing.T) {
+	var got string
+	h := RealIP(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+
+	h(httptest.NewRecorder(), req)
+
+	if got != "127.0.0.1:1234" {
+		t.Fatalf("RemoteAddr = %q, want it left untouched", got)
+	}
+}
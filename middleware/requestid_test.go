@@ -0,0 +1,41 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDRoundTripsThroughContextAndHeader(t *testing.T) {
+	var fromCtx string
+	var ok bool
+
+	h := RequestID(func(w http.ResponseWriter, r *http.Request) {
+		fromCtx, ok = RequestIDFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h(rec, req)
+
+	if !ok || fromCtx == "" {
+		t.Fatal("expected RequestIDFromContext to return the id RequestID injected")
+	}
+
+	if header := rec.Header().Get("X-Request-Id"); header != fromCtx {
+		t.Fatalf("X-Request-Id header = %q, want it to match the context id %q", header, fromCtx)
+	}
+}
+
+func TestRequestIDFromContextMissing(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if _, ok := RequestIDFromContext(req.Context()); ok {
+		t.Fatal("expected no request id on a plain request context")
+	}
+}
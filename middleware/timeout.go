@@ -0,0 +1,116 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout wraps the handler in a context with a d deadline, and responds
+// 504 Gateway Timeout if the handler hasn't returned by the time it
+// elapses. The handler keeps running in the background after
+// the timeout fires, since it's not safe to abandon it mid-write, so its
+// writes go through a buffering ResponseWriter: they're copied onto the
+// real one if the handler finishes in time, and discarded otherwise,
+// instead of racing with the timeout response on the real ResponseWriter.
+func Timeout(d time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+
+			go func() {
+				next(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				tw.flushTo(w)
+			case <-ctx.Done():
+				tw.discard()
+				http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+			}
+		}
+	}
+}
+
+// timeoutWriter buffers a handler's response instead of writing it
+// straight to the real http.ResponseWriter, so Timeout can decide once,
+// after the handler finishes or the deadline fires, whether to copy it
+// over or drop it. This keeps the handler's goroutine from ever touching
+// the real ResponseWriter concurrently with the main goroutine.
+type timeoutWriter struct {
+	mu        sync.Mutex
+	header    http.Header
+	body      bytes.Buffer
+	status    int
+	discarded bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.discarded || tw.status != 0 {
+		return
+	}
+
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.discarded {
+		return len(b), nil
+	}
+
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+
+	return tw.body.Write(b)
+}
+
+// discard marks tw so that any write the handler goroutine makes from
+// this point on is silently dropped instead of buffered.
+func (tw *timeoutWriter) discard() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.discarded = true
+}
+
+// flushTo copies the buffered response onto w. Only called once the
+// handler goroutine has finished (signalled via the done channel), so
+// there's no concurrent access to tw left to guard against.
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	dst := w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+
+	if tw.status != 0 {
+		w.WriteHeader(tw.status)
+	}
+
+	w.Write(tw.body.Bytes())
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutFiresAndDiscardsLateWrite(t *testing.T) {
+	release := make(chan struct{})
+
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("late write"))
+	}
+
+	h := Timeout(20 * time.Millisecond)(slow)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h(rec, req)
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+
+	if rec.Body.String() != http.StatusText(http.StatusGatewayTimeout)+"\n" {
+		t.Fatalf("body = %q, expected only the timeout response, no late handler output", rec.Body.String())
+	}
+}
+
+func TestTimeoutFlushesHandlerResponseWhenNotExceeded(t *testing.T) {
+	fast := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "1")
+		w.Write([]byte("ok"))
+	}
+
+	h := Timeout(time.Second)(fast)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" || rec.Header().Get("X-Test") != "1" {
+		t.Fatalf("unexpected response: status=%d body=%q header=%q", rec.Code, rec.Body.String(), rec.Header().Get("X-Test"))
+	}
+}
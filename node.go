@@ -0,0 +1,316 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package sariaf
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// nodeType identifies what kind of path segment a node matches.
+type nodeType uint8
+
+const (
+	staticNode nodeType = iota
+	paramNode
+	catchAllNode
+)
+
+// node is a node in the compressed radix tree backing each method's route
+// tree. A static node's path can hold several literal path bytes at once,
+// so routes sharing a common prefix share the nodes for that prefix
+// instead of branching on every "/". Matching walks the tree with a
+// byte-scan (strings.HasPrefix) rather than splitting the request path
+// into a slice and doing a map lookup per segment.
+type node struct {
+	path     string
+	nType    nodeType
+	param    string
+	priority uint32
+	children []*node
+	handler  http.HandlerFunc
+}
+
+// add inserts path into the tree rooted at n, associating it with
+// handler. Static text is compressed onto shared nodes; ":name" and
+// "*name" segments get a dedicated param/catch-all child, same as
+// before, but children are now kept sorted by priority (routes
+// registered below them) so the common case is found with fewer
+// comparisons.
+func (n *node) add(path string, handler http.HandlerFunc) {
+	current := n
+	remaining := path
+
+	for len(remaining) > 0 {
+		if remaining[0] == ':' || remaining[0] == '*' {
+			isCatchAll := remaining[0] == '*'
+			slash := strings.IndexByte(remaining, '/')
+
+			if isCatchAll && slash != -1 {
+				panic("sariaf: catch-all must be the last segment in path '" + path + "'")
+			}
+
+			end := slash
+			if isCatchAll || end == -1 {
+				end = len(remaining)
+			}
+
+			name := remaining[1:end]
+			if name == "" {
+				panic("sariaf: missing param name in path '" + path + "'")
+			}
+
+			current = current.addWildcardChild(name, isCatchAll)
+			remaining = remaining[end:]
+
+			continue
+		}
+
+		end := nextSegmentWildcard(remaining)
+		if end == -1 {
+			end = len(remaining)
+		}
+
+		current = current.addStaticChild(remaining[:end])
+		remaining = remaining[end:]
+	}
+
+	current.handler = handler
+}
+
+// addStaticChild walks or creates static children of n so that text is
+// fully represented as path bytes from n, splitting existing children on
+// a partial prefix match, and returns the node at the end of text.
+func (n *node) addStaticChild(text string) *node {
+	current := n
+
+	for len(text) > 0 {
+		var matched *node
+		for _, c := range current.children {
+			if c.nType == staticNode && c.path[0] == text[0] {
+				matched = c
+				break
+			}
+		}
+
+		if matched == nil {
+			for _, c := range current.children {
+				if c.nType == catchAllNode {
+					panic("sariaf: catch-all conflicts with existing route under this path")
+				}
+			}
+
+			child := &node{path: text, nType: staticNode, priority: 1}
+			current.children = append(current.children, child)
+			current.sortChildren()
+
+			return child
+		}
+
+		i := longestCommonPrefix(text, matched.path)
+
+		if i < len(matched.path) {
+			// split matched at i, pushing its remainder (and everything
+			// below it) into a new child, so the shared prefix can live
+			// on matched alone.
+			tail := &node{
+				path:     matched.path[i:],
+				nType:    staticNode,
+				children: matched.children,
+				handler:  matched.handler,
+				priority: matched.priority,
+			}
+
+			matched.path = matched.path[:i]
+			matched.children = []*node{tail}
+			matched.handler = nil
+		}
+
+		matched.priority++
+		current.sortChildren()
+		current = matched
+		text = text[i:]
+	}
+
+	return current
+}
+
+// addWildcardChild returns n's single param or catch-all child named
+// name, creating it if absent. A node can have at most one such child,
+// since a segment can't be both literal text and a parameter; a
+// catch-all additionally can't coexist with any sibling, static or
+// otherwise, since it consumes the rest of the path.
+func (n *node) addWildcardChild(name string, isCatchAll bool) *node {
+	wantType := paramNode
+	if isCatchAll {
+		wantType = catchAllNode
+	}
+
+	for _, c := range n.children {
+		if c.nType == paramNode || c.nType == catchAllNode {
+			if c.nType != wantType || c.param != name {
+				panic("sariaf: conflicting wildcard registered for this path segment")
+			}
+
+			c.priority++
+			n.sortChildren()
+			return c
+		}
+	}
+
+	if isCatchAll && len(n.children) > 0 {
+		panic("sariaf: catch-all conflicts with existing route under this path")
+	}
+
+	child := &node{nType: wantType, param: name, priority: 1}
+	n.children = append(n.children, child)
+	n.sortChildren()
+
+	return child
+}
+
+// sortChildren orders n's children by descending priority, so find tries
+// the most-routed-through branches first.
+func (n *node) sortChildren() {
+	sort.SliceStable(n.children, func(i, j int) bool {
+		return n.children[i].priority > n.children[j].priority
+	})
+}
+
+// find walks the tree matching path against static prefixes byte by
+// byte, falling back to param and catch-all children, and returns the
+// matched node along with any params captured along the way. Static
+// children are tried before the param child (itself tried before a
+// catch-all), so literal routes like "/users/profile" win over
+// "/users/:id" registered alongside them.
+func (n *node) find(path string) (*node, Params) {
+	var params Params
+	current := n
+	remaining := path
+
+walk:
+	for {
+		if current.nType == staticNode {
+			if !strings.HasPrefix(remaining, current.path) {
+				return nil, params
+			}
+
+			remaining = remaining[len(current.path):]
+		}
+
+		if remaining == "" {
+			return current, params
+		}
+
+		// static children are tried first, regardless of their relative
+		// priority order, so a literal route always wins over a :param
+		// or *catchall registered alongside it. A static branch with no
+		// route for the rest of the path falls back to the param and
+		// catch-all children below.
+		for _, c := range current.children {
+			if c.nType == staticNode && strings.HasPrefix(remaining, c.path) {
+				if found, p := c.find(remaining); found != nil && found.handler != nil {
+					return found, append(params, p...)
+				}
+			}
+		}
+
+		for _, c := range current.children {
+			if c.nType != paramNode {
+				continue
+			}
+
+			end := strings.IndexByte(remaining, '/')
+			if end == -1 {
+				end = len(remaining)
+			}
+
+			if end == 0 {
+				continue
+			}
+
+			params = append(params, Param{Key: c.param, Value: remaining[:end]})
+			remaining = remaining[end:]
+			current = c
+
+			continue walk
+		}
+
+		for _, c := range current.children {
+			if c.nType == catchAllNode {
+				params = append(params, Param{Key: c.param, Value: remaining})
+				return c, params
+			}
+		}
+
+		return nil, params
+	}
+}
+
+// nextSegmentWildcard returns the index of the next ':' or '*' that
+// starts a path segment in text, i.e. one immediately following a '/',
+// or -1 if there isn't one. A ':' or '*' appearing mid-segment (as in
+// the literal path "/foo:bar") doesn't count, so it's kept as part of
+// the static text instead of being parsed as a param.
+func nextSegmentWildcard(text string) int {
+	for i := 0; i < len(text)-1; i++ {
+		if text[i] == '/' && (text[i+1] == ':' || text[i+1] == '*') {
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// longestCommonPrefix returns the length of the longest common prefix of
+// a and b.
+func longestCommonPrefix(a, b string) int {
+	max := len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+
+	i := 0
+	for i < max && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
+
+// mountedRoute pairs a path registered on a node's trie with its handler,
+// used by Mount to collect every route registered below a node.
+type mountedRoute struct {
+	path    string
+	handler http.HandlerFunc
+}
+
+// routes walks the tree rooted at n, reconstructing each route's full
+// registered path, and returns every route found below n.
+func (n *node) routes(prefix string) []mountedRoute {
+	path := prefix
+
+	switch n.nType {
+	case staticNode:
+		path += n.path
+	case paramNode:
+		path += ":" + n.param
+	case catchAllNode:
+		path += "*" + n.param
+	}
+
+	var found []mountedRoute
+
+	if n.handler != nil {
+		found = append(found, mountedRoute{path: path, handler: n.handler})
+	}
+
+	for _, child := range n.children {
+		found = append(found, child.routes(path)...)
+	}
+
+	return found
+}
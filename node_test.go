@@ -0,0 +1,126 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package sariaf
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newRoot() *node {
+	return &node{nType: staticNode}
+}
+
+func TestStaticBeatsParam(t *testing.T) {
+	rt := newRoot()
+	rt.add("/users/:id", func(w http.ResponseWriter, r *http.Request) {})
+	rt.add("/users/profile", func(w http.ResponseWriter, r *http.Request) {})
+
+	n, params := rt.find("/users/profile")
+	if n == nil || n.handler == nil {
+		t.Fatal("expected /users/profile to match the static route")
+	}
+	if len(params) != 0 {
+		t.Fatalf("expected no params for the static route, got %v", params)
+	}
+
+	n, params = rt.find("/users/42")
+	if n == nil || n.handler == nil {
+		t.Fatal("expected /users/42 to match the :id route")
+	}
+	if got := params.ByName("id"); got != "42" {
+		t.Fatalf("id = %q, want 42", got)
+	}
+}
+
+func TestCatchAllBeatsNothingElseMatches(t *testing.T) {
+	rt := newRoot()
+	rt.add("/static/*filepath", func(w http.ResponseWriter, r *http.Request) {})
+
+	n, params := rt.find("/static/css/a.css")
+	if n == nil || n.handler == nil {
+		t.Fatal("expected /static/css/a.css to match the catch-all route")
+	}
+	if got := params.ByName("filepath"); got != "css/a.css" {
+		t.Fatalf("filepath = %q, want css/a.css", got)
+	}
+}
+
+func TestCatchAllMustBeLastSegment(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for /static/*filepath/extra")
+		}
+	}()
+
+	rt := newRoot()
+	rt.add("/static/*filepath/extra", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestCatchAllConflictsWithStaticSibling(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when adding a catch-all alongside a static route")
+		}
+	}()
+
+	rt := newRoot()
+	rt.add("/static/logo.png", func(w http.ResponseWriter, r *http.Request) {})
+	rt.add("/static/*filepath", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestStaticConflictsWithExistingCatchAll(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when adding a static route alongside an existing catch-all")
+		}
+	}()
+
+	rt := newRoot()
+	rt.add("/static/*filepath", func(w http.ResponseWriter, r *http.Request) {})
+	rt.add("/static/logo.png", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestConflictingCatchAllParamName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for conflicting catch-all param names")
+		}
+	}()
+
+	rt := newRoot()
+	rt.add("/static/*filepath", func(w http.ResponseWriter, r *http.Request) {})
+	rt.add("/static/*rest", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestColonNotAtSegmentBoundaryStaysLiteral(t *testing.T) {
+	rt := newRoot()
+	rt.add("/foo:bar", func(w http.ResponseWriter, r *http.Request) {})
+
+	if n, _ := rt.find("/fooXYZ"); n != nil {
+		t.Fatalf("expected no match for /fooXYZ, got %v", n)
+	}
+
+	n, params := rt.find("/foo:bar")
+	if n == nil || n.handler == nil {
+		t.Fatal("expected the literal path /foo:bar to match itself")
+	}
+	if len(params) != 0 {
+		t.Fatalf("expected no params, got %v", params)
+	}
+}
+
+func TestParamAtSegmentBoundaryStillParses(t *testing.T) {
+	rt := newRoot()
+	rt.add("/foo/:bar", func(w http.ResponseWriter, r *http.Request) {})
+
+	n, params := rt.find("/foo/baz")
+	if n == nil || n.handler == nil {
+		t.Fatal("expected /foo/:bar to match /foo/baz")
+	}
+	if got := params.ByName("bar"); got != "baz" {
+		t.Fatalf("bar = %q, want baz", got)
+	}
+}
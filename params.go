@@ -0,0 +1,58 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package sariaf
+
+import "context"
+
+// Param is a single path parameter captured while matching a request.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params holds the path parameters matched for a request, in the order
+// they appear in the path. It's a slice rather than a map so matching a
+// request doesn't need a map allocation.
+type Params []Param
+
+// ByName returns the value of the first param named name, or "" if there
+// isn't one.
+func (p Params) ByName(name string) string {
+	for _, param := range p {
+		if param.Key == name {
+			return param.Value
+		}
+	}
+
+	return ""
+}
+
+// AsMap returns params as a map[string]string, for code written against
+// the old map-based Params type.
+func (p Params) AsMap() map[string]string {
+	m := make(map[string]string, len(p))
+	for _, param := range p {
+		m[param.Key] = param.Value
+	}
+
+	return m
+}
+
+type contextKeyType struct{}
+
+// contextKey is the context key for the params.
+var contextKey = contextKeyType{}
+
+// newContext returns a new Context that carries a provided params value.
+func newContext(ctx context.Context, params Params) context.Context {
+	return context.WithValue(ctx, contextKey, params)
+}
+
+// fromContext extracts params from a Context.
+func fromContext(ctx context.Context) (Params, bool) {
+	values, ok := ctx.Value(contextKey).(Params)
+
+	return values, ok
+}
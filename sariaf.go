@@ -5,160 +5,123 @@
 package sariaf
 
 import (
-	"context"
 	"net/http"
+	"sort"
 	"strings"
 )
 
-// each node represent a path in the router trie.
-type node struct {
-	path     string
-	key      string
-	children map[string]*node
-	handler  http.HandlerFunc
-	param    string
-}
-
-// add method adds a new path to the trie.
-func (n *node) add(path string, handler http.HandlerFunc) {
-	current := n
-
-	trimmed := strings.TrimPrefix(path, "/")
-	slice := strings.Split(trimmed, "/")
-
-	for _, k := range slice {
-		// replace keys with pattern ":*" with "*" for matching params.
-		var param string
-		if len(k) > 1 && string(k[0]) == ":" {
-			param = strings.TrimPrefix(k, ":")
-			k = "*"
-		}
-
-		next, ok := current.children[k]
-		if !ok {
-			next = &node{
-				path:     path,
-				key:      k,
-				children: make(map[string]*node),
-				param:    param,
-			}
-			current.children[k] = next
-		}
-		current = next
-	}
-
-	current.handler = handler
-}
-
-// find method match the request url path with a node in trie.
-func (n *node) find(path string) (*node, Params) {
-	params := make(Params)
-	current := n
-
-	trimmed := strings.TrimPrefix(path, "/")
-	slice := strings.Split(trimmed, "/")
-
-	for _, k := range slice {
-		var next *node
-
-		next, ok := current.children[k]
-		if !ok {
-			next, ok = current.children["*"]
-			if !ok {
-				// return nil if no node match the given path.
-				return nil, params
-			}
-
-		}
-
-		current = next
-
-		// if the node has a param add it to params map.
-		if current.param != "" {
-			params[current.param] = k
-		}
-	}
-
-	// return the found node and params map.
-	return current, params
-}
-
-type contextKeyType struct{}
-
-// Params is the type for request params.
-type Params map[string]string
-
-// contextKey is the context key for the params.
-var contextKey = contextKeyType{}
-
-// newContext returns a new Context that carries a provided params value.
-func newContext(ctx context.Context, params Params) context.Context {
-	return context.WithValue(ctx, contextKey, params)
-}
-
-// fromContext extracts params from a Context.
-func fromContext(ctx context.Context) (Params, bool) {
-	values, ok := ctx.Value(contextKey).(Params)
-
-	return values, ok
-}
-
 // Router is an HTTP request multiplexer. It matches the URL of each
 // incoming request against a list of registered path with their associated
 // methods and calls the handler for the given URL.
 type Router struct {
-	trees       map[string]*node
-	middlewares []func(http.HandlerFunc) http.HandlerFunc
+	trees            map[string]*node
+	middlewares      []func(http.HandlerFunc) http.HandlerFunc
+	notFound         http.HandlerFunc
+	methodNotAllowed http.HandlerFunc
+
+	// HandleOPTIONS, when true (the default), makes the router answer
+	// OPTIONS requests itself with an Allow header listing the methods
+	// registered for the path, unless the user registered their own
+	// OPTIONS handler for it.
+	HandleOPTIONS bool
 }
 
 // New returns a new Router.
 func New() *Router {
 	return &Router{
-		trees: make(map[string]*node),
+		trees:         make(map[string]*node),
+		HandleOPTIONS: true,
 	}
 }
 
+// NotFound registers h as the handler called when no route, under any
+// method, matches the request path. It replaces the default http.NotFound.
+func (r *Router) NotFound(h http.HandlerFunc) {
+	r.notFound = h
+}
+
+// MethodNotAllowed registers h as the handler called when the request path
+// matches a route registered under a different method. It replaces the
+// default plain-text 405 response.
+func (r *Router) MethodNotAllowed(h http.HandlerFunc) {
+	r.methodNotAllowed = h
+}
+
 // ServeHTTP matches r.URL.Path with a stored route and calls handler for found node.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	// check if there is a trie for the request method.
-	if _, ok := r.trees[req.Method]; !ok {
-		http.NotFound(w, req)
-		return
+	// find the node with request url path in the trie for the request method.
+	if tree, ok := r.trees[req.Method]; ok {
+		node, params := tree.find(req.URL.Path)
+
+		if node != nil && node.handler != nil {
+			// attach the params context to request if any param exists.
+			if len(params) != 0 {
+				ctx := newContext(req.Context(), params)
+				req = req.WithContext(ctx)
+			}
+
+			// call the middlewares on handler
+			var handler = node.handler
+			for _, middleware := range r.middlewares {
+				handler = middleware(handler)
+			}
+
+			// call the node handler
+			handler(w, req)
+			return
+		}
 	}
 
-	// find the node with request url path in the trie.
-	node, params := r.trees[req.Method].find(req.URL.Path)
+	// the path isn't registered under this method, but it might be under
+	// another one: respond 405 with the Allow header, or auto-answer
+	// OPTIONS, instead of a flat 404.
+	if allowed := r.allowedMethods(req.URL.Path); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
 
-	if node != nil && node.handler != nil {
-		// attach the params context to request if any param exists.
-		if len(params) != 0 {
-			ctx := newContext(req.Context(), params)
-			req = req.WithContext(ctx)
+		if req.Method == http.MethodOptions && r.HandleOPTIONS {
+			w.WriteHeader(http.StatusOK)
+			return
 		}
 
-		// call the middlewares on handler
-		var handler = node.handler
-		for _, middleware := range r.middlewares {
-			handler = middleware(handler)
+		if r.methodNotAllowed != nil {
+			r.methodNotAllowed(w, req)
+		} else {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 		}
 
-		// call the node handler
-		handler(w, req)
 		return
 	}
 
 	// call the not found handler if can match the request url path to any node in trie.
-	http.NotFound(w, req)
+	if r.notFound != nil {
+		r.notFound(w, req)
+	} else {
+		http.NotFound(w, req)
+	}
+}
+
+// allowedMethods returns the sorted list of methods, across every method's
+// trie, that have a route matching path. Used to build the Allow header
+// for 405 and auto-handled OPTIONS responses.
+func (r *Router) allowedMethods(path string) []string {
+	var methods []string
+
+	for method, tree := range r.trees {
+		if node, _ := tree.find(path); node != nil && node.handler != nil {
+			methods = append(methods, method)
+		}
+	}
+
+	sort.Strings(methods)
+
+	return methods
 }
 
 // Handle registers a new path with the given path and method.
 func (r *Router) Handle(method string, path string, handler http.HandlerFunc) {
 	// check if for given method there is not any tie create a new one.
 	if _, ok := r.trees[method]; !ok {
-		r.trees[method] = &node{
-			path:     "/",
-			children: make(map[string]*node),
-		}
+		r.trees[method] = &node{nType: staticNode}
 	}
 
 	r.trees[method].add(path, handler)
@@ -175,3 +138,67 @@ func (r *Router) Use(middlewares ...func(http.HandlerFunc) http.HandlerFunc) {
 		r.middlewares = append(r.middlewares, middlewares...)
 	}
 }
+
+// ServeFiles registers a GET handler serving files out of root for every
+// path matching path, which must end in "/*filepath" (the catch-all
+// segment is how the requested file's path is captured).
+func (r *Router) ServeFiles(path string, root http.FileSystem) {
+	if !strings.HasSuffix(path, "/*filepath") {
+		panic("sariaf: path must end with '/*filepath' in path '" + path + "'")
+	}
+
+	fileServer := http.FileServer(root)
+
+	r.Handle(http.MethodGet, path, func(w http.ResponseWriter, req *http.Request) {
+		params, _ := GetParams(req)
+
+		req.URL.Path = "/" + params.ByName("filepath")
+		fileServer.ServeHTTP(w, req)
+	})
+}
+
+// Mount attaches every route registered on sub under prefix, so requests
+// matching prefix are routed to sub's handlers. The child's own
+// middlewares are inlined around each of its handlers, so they only run
+// for requests under the mount point, while the parent's middlewares
+// keep applying to every request as usual. Params captured by :name
+// segments inside prefix itself are preserved, since the combined path
+// is re-added through the normal Handle/add machinery.
+func (r *Router) Mount(prefix string, sub *Router) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	for method, root := range sub.trees {
+		for _, route := range root.routes("") {
+			handler := route.handler
+			for _, middleware := range sub.middlewares {
+				handler = middleware(handler)
+			}
+
+			r.Handle(method, prefix+route.path, handler)
+		}
+	}
+}
+
+// Group creates an inline Router, passes it to fn for route and
+// middleware registration, and mounts its routes back onto r without a
+// path prefix. It's used to apply additional middlewares to a subset of
+// routes, e.g. requiring auth on some routes but not others.
+func (r *Router) Group(fn func(r *Router)) *Router {
+	sub := New()
+	fn(sub)
+	r.Mount("", sub)
+
+	return sub
+}
+
+// Route creates an inline Router scoped under prefix, passes it to fn for
+// route and middleware registration, and mounts it onto r. It's the
+// combination of Group and Mount, for composing a subrouter like
+// `/api/v1` with its own middleware stack.
+func (r *Router) Route(prefix string, fn func(r *Router)) *Router {
+	sub := New()
+	fn(sub)
+	r.Mount(prefix, sub)
+
+	return sub
+}
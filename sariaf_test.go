@@ -0,0 +1,250 @@
+// Copyright 2020 Majid Sajadi. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be found
+// in the LICENSE file.
+
+package sariaf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMountComposesPrefixAndParams(t *testing.T) {
+	r := New()
+	sub := New()
+
+	sub.Handle(http.MethodGet, "/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		params, _ := GetParams(req)
+		w.Write([]byte(params.ByName("account") + ":" + params.ByName("id")))
+	})
+
+	r.Mount("/accounts/:account", sub)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/acme/users/42", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "acme:42" {
+		t.Fatalf("body = %q, want acme:42", rec.Body.String())
+	}
+}
+
+func TestMountOrdersParentMiddlewareOutsideChild(t *testing.T) {
+	var order []string
+
+	tag := func(name string) func(http.HandlerFunc) http.HandlerFunc {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name+":before")
+				next(w, r)
+				order = append(order, name+":after")
+			}
+		}
+	}
+
+	r := New()
+	r.Use(tag("parent"))
+
+	sub := New()
+	sub.Use(tag("child"))
+	sub.Handle(http.MethodGet, "/ping", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	r.Mount("/api", sub)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	want := []string{"parent:before", "child:before", "handler", "child:after", "parent:after"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+// TestMountSnapshotsRoutesAtCallTime pins down that Mount copies sub's
+// routes as they exist at the moment it's called: routes registered on
+// sub afterwards are not retroactively picked up by the parent.
+func TestMountSnapshotsRoutesAtCallTime(t *testing.T) {
+	r := New()
+	sub := New()
+
+	sub.Handle(http.MethodGet, "/before", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("before"))
+	})
+
+	r.Mount("/api", sub)
+
+	sub.Handle(http.MethodGet, "/after", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("after"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/before", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Body.String() != "before" {
+		t.Fatalf("body = %q, want before", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/after", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: route added to sub after Mount should not be picked up", rec.Code)
+	}
+}
+
+func TestGroupMountsWithoutPrefix(t *testing.T) {
+	r := New()
+
+	r.Group(func(sub *Router) {
+		sub.Handle(http.MethodGet, "/grouped", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("grouped"))
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/grouped", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "grouped" {
+		t.Fatalf("body = %q, want grouped", rec.Body.String())
+	}
+}
+
+func TestRouteMountsUnderPrefixWithOwnMiddleware(t *testing.T) {
+	r := New()
+
+	r.Route("/v1", func(sub *Router) {
+		sub.Use(func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Scoped", "1")
+				next(w, r)
+			}
+		})
+		sub.Handle(http.MethodGet, "/ping", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("pong"))
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/ping", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "pong" || rec.Header().Get("X-Scoped") != "1" {
+		t.Fatalf("unexpected response: body=%q header=%q", rec.Body.String(), rec.Header().Get("X-Scoped"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: /ping should only exist under /v1", rec.Code)
+	}
+}
+
+func TestMethodNotAllowedListsAllowedMethods(t *testing.T) {
+	r := New()
+	r.Handle(http.MethodGet, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+	r.Handle(http.MethodPost, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodDelete, "/widgets", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, POST" {
+		t.Fatalf("Allow = %q, want %q", got, "GET, POST")
+	}
+}
+
+func TestOptionsAutoRespondsWithAllowHeader(t *testing.T) {
+	r := New()
+	r.Handle(http.MethodGet, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+	r.Handle(http.MethodPost, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, POST" {
+		t.Fatalf("Allow = %q, want %q", got, "GET, POST")
+	}
+}
+
+func TestOptionsFallsThroughToRegisteredHandler(t *testing.T) {
+	r := New()
+	r.Handle(http.MethodGet, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+	r.Handle(http.MethodOptions, "/widgets", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("custom options"))
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "custom options" {
+		t.Fatalf("body = %q, want the user's own OPTIONS handler to run", rec.Body.String())
+	}
+}
+
+func TestHandleOPTIONSDisabledFallsBackToMethodNotAllowed(t *testing.T) {
+	r := New()
+	r.HandleOPTIONS = false
+	r.Handle(http.MethodGet, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405 when HandleOPTIONS is false", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET" {
+		t.Fatalf("Allow = %q, want %q", got, "GET")
+	}
+}
+
+func TestServeFilesServesAFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/a.css": &fstest.MapFile{Data: []byte("body { color: red; }")},
+	}
+
+	r := New()
+	r.ServeFiles("/static/*filepath", http.FS(fsys))
+
+	req := httptest.NewRequest(http.MethodGet, "/static/css/a.css", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Body.String() != "body { color: red; }" {
+		t.Fatalf("body = %q, want the served file's contents", rec.Body.String())
+	}
+}
+
+func TestServeFilesPanicsWithoutCatchAllSuffix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected ServeFiles to panic for a path not ending in /*filepath")
+		}
+	}()
+
+	r := New()
+	r.ServeFiles("/static", http.FS(fstest.MapFS{}))
+}